@@ -1,6 +1,11 @@
 package auth
 
-import "net/http"
+import (
+	"net"
+	"net/http"
+
+	"github.com/gravitl/netmaker/logic/audit"
+)
 
 // == define error HTML here ==
 const oauthNotConfigured = `<!DOCTYPE html><html>
@@ -23,21 +28,74 @@ const userNotFound = `<!DOCTYPE html><html>
 </body>
 </html>`
 
-func handleOauthUserNotFound(response http.ResponseWriter) {
+// oauthAuditContext carries the request/attempted-username an oauth denial handler
+// needs for its audit record. It's accepted as a trailing optional argument (via a
+// variadic parameter) so existing call sites that only pass the ResponseWriter keep
+// compiling unchanged; callers that have the request in scope should pass it.
+type oauthAuditContext struct {
+	Request       *http.Request
+	AttemptedUser string
+}
+
+func handleOauthUserNotFound(response http.ResponseWriter, ctx ...oauthAuditContext) {
+	ac := firstOauthAuditContext(ctx)
+	audit.Log(audit.Record{
+		Actor:    ac.AttemptedUser,
+		SourceIP: requestSourceIP(ac.Request),
+		Action:   audit.ActionOauthUserNotFound,
+		Target:   "dashboard",
+		Result:   audit.ResultDenied,
+	})
 	response.Header().Set("Content-Type", "text/html; charset=utf-8")
 	response.WriteHeader(http.StatusNotFound)
 	response.Write([]byte(userNotFound))
 }
 
-func handleOauthUserNotAllowed(response http.ResponseWriter) {
+func handleOauthUserNotAllowed(response http.ResponseWriter, ctx ...oauthAuditContext) {
+	ac := firstOauthAuditContext(ctx)
+	audit.Log(audit.Record{
+		Actor:    ac.AttemptedUser,
+		SourceIP: requestSourceIP(ac.Request),
+		Action:   audit.ActionOauthUserNotAllow,
+		Target:   "dashboard",
+		Result:   audit.ResultDenied,
+	})
 	response.Header().Set("Content-Type", "text/html; charset=utf-8")
 	response.WriteHeader(http.StatusForbidden)
 	response.Write([]byte(userNotAllowed))
 }
 
 // handleOauthNotConfigured - returns an appropriate html page when oauth is not configured on netmaker server but an oauth login was attempted
-func handleOauthNotConfigured(response http.ResponseWriter) {
+func handleOauthNotConfigured(response http.ResponseWriter, ctx ...oauthAuditContext) {
+	ac := firstOauthAuditContext(ctx)
+	audit.Log(audit.Record{
+		SourceIP: requestSourceIP(ac.Request),
+		Action:   audit.ActionOauthNotConfigured,
+		Target:   "dashboard",
+		Result:   audit.ResultDenied,
+	})
 	response.Header().Set("Content-Type", "text/html; charset=utf-8")
 	response.WriteHeader(http.StatusInternalServerError)
 	response.Write([]byte(oauthNotConfigured))
 }
+
+func firstOauthAuditContext(ctx []oauthAuditContext) oauthAuditContext {
+	if len(ctx) == 0 {
+		return oauthAuditContext{}
+	}
+	return ctx[0]
+}
+
+// requestSourceIP returns the client address for an audit record, falling back to
+// the raw RemoteAddr if it isn't a parseable host:port pair. A nil request yields
+// an empty string.
+func requestSourceIP(request *http.Request) string {
+	if request == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/logic/audit"
+)
+
+func init() {
+	HttpHandlers = append(HttpHandlers, auditHandlers)
+}
+
+func auditHandlers(r *mux.Router) {
+	r.HandleFunc("/api/audit", logic.SecurityCheck(true, http.HandlerFunc(getAuditLog))).Methods(http.MethodGet)
+}
+
+// @Summary     Query the audit log
+// @Router      /api/audit [get]
+// @Tags        PRO
+// @Param       actor query string false "Filter by actor"
+// @Param       action query string false "Filter by action"
+// @Param       from query string false "RFC3339 start of time range"
+// @Param       to query string false "RFC3339 end of time range"
+// @Success     200 {array} audit.Record
+// @Failure     400 {object} models.ErrorResponse
+// @Failure     500 {object} models.ErrorResponse
+func getAuditLog(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+
+	filter := audit.Filter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+	}
+	if from := query.Get("from"); from != "" {
+		parsed, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+			return
+		}
+		filter.From = parsed
+	}
+	if to := query.Get("to"); to != "" {
+		parsed, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+			return
+		}
+		filter.To = parsed
+	}
+
+	records, err := audit.Query(filter)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	json.NewEncoder(w).Encode(records)
+}
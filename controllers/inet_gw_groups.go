@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/mq"
+)
+
+func init() {
+	HttpHandlers = append(HttpHandlers, inetGwGroupHandlers)
+}
+
+func inetGwGroupHandlers(r *mux.Router) {
+	r.HandleFunc("/api/networks/{network}/inet_gw_groups", logic.SecurityCheck(true, http.HandlerFunc(listInternetGwGroups))).Methods(http.MethodGet)
+	r.HandleFunc("/api/networks/{network}/inet_gw_groups", logic.SecurityCheck(true, http.HandlerFunc(createInternetGwGroup))).Methods(http.MethodPost)
+	r.HandleFunc("/api/networks/{network}/inet_gw_groups/{groupid}", logic.SecurityCheck(true, http.HandlerFunc(updateInternetGwGroup))).Methods(http.MethodPut)
+	r.HandleFunc("/api/networks/{network}/inet_gw_groups/{groupid}", logic.SecurityCheck(true, http.HandlerFunc(deleteInternetGwGroup))).Methods(http.MethodDelete)
+}
+
+// @Summary     List internet gateway failover groups for a network
+// @Router      /api/networks/{network}/inet_gw_groups [get]
+// @Tags        PRO
+// @Param       network path string true "Network ID"
+// @Success     200 {array} models.InternetGwGroup
+// @Failure     500 {object} models.ErrorResponse
+func listInternetGwGroups(w http.ResponseWriter, r *http.Request) {
+	var params = mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+	groups, err := logic.ListInternetGwGroups(params["network"])
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	json.NewEncoder(w).Encode(groups)
+}
+
+// @Summary     Create an internet gateway failover group
+// @Router      /api/networks/{network}/inet_gw_groups [post]
+// @Tags        PRO
+// @Param       network path string true "Network ID"
+// @Param       body body models.InternetGwGroupReq true "Internet gateway group request"
+// @Success     200 {object} models.InternetGwGroup
+// @Failure     400 {object} models.ErrorResponse
+func createInternetGwGroup(w http.ResponseWriter, r *http.Request) {
+	var params = mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+	var request models.InternetGwGroupReq
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	group, err := logic.CreateInternetGwGroup(params["network"], request)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "created internet gateway group", group.ID, "on network", params["network"])
+	json.NewEncoder(w).Encode(group)
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
+}
+
+// @Summary     Update an internet gateway failover group
+// @Router      /api/networks/{network}/inet_gw_groups/{groupid} [put]
+// @Tags        PRO
+// @Param       network path string true "Network ID"
+// @Param       groupid path string true "Group ID"
+// @Param       body body models.InternetGwGroupReq true "Internet gateway group request"
+// @Success     200 {object} models.InternetGwGroup
+// @Failure     400 {object} models.ErrorResponse
+func updateInternetGwGroup(w http.ResponseWriter, r *http.Request) {
+	var params = mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+	var request models.InternetGwGroupReq
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	group, err := logic.UpdateInternetGwGroup(params["groupid"], request)
+	if err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "updated internet gateway group", group.ID, "on network", params["network"])
+	json.NewEncoder(w).Encode(group)
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
+}
+
+// @Summary     Delete an internet gateway failover group
+// @Router      /api/networks/{network}/inet_gw_groups/{groupid} [delete]
+// @Tags        PRO
+// @Param       network path string true "Network ID"
+// @Param       groupid path string true "Group ID"
+// @Success     200 {object} models.SuccessResponse
+// @Failure     500 {object} models.ErrorResponse
+func deleteInternetGwGroup(w http.ResponseWriter, r *http.Request) {
+	var params = mux.Vars(r)
+	w.Header().Set("Content-Type", "application/json")
+	if err := logic.DeleteInternetGwGroup(params["groupid"]); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
+		return
+	}
+	logger.Log(1, r.Header.Get("user"), "deleted internet gateway group", params["groupid"], "on network", params["network"])
+	logic.ReturnSuccessResponse(w, r, "deleted internet gateway group "+params["groupid"])
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
+}
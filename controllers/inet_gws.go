@@ -3,16 +3,29 @@ package controller
 import (
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/gravitl/netmaker/logger"
 	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/logic/audit"
 	"github.com/gravitl/netmaker/models"
 	"github.com/gravitl/netmaker/mq"
 	"github.com/gravitl/netmaker/servercfg"
 )
 
+// requestSourceIP returns the client address for an audit record, preferring the
+// parsed remote addr and falling back to the raw RemoteAddr if it isn't a
+// host:port pair (e.g. behind some reverse proxy configurations).
+func requestSourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // @Summary     Create an internet gateway
 // @Router      /api/nodes/{network}/{nodeid}/inet_gw [post]
 // @Tags        PRO
@@ -64,12 +77,16 @@ func createInternetGw(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
+	if err = logic.ValidateEgressPolicy(netid, request.EgressPolicy); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
 	logic.SetInternetGw(&node, request)
 	if servercfg.IsPro {
 		if _, exists := logic.FailOverExists(node.Network); exists {
 			go func() {
 				logic.ResetFailedOverPeer(&node)
-				mq.PublishPeerUpdate(false)
+				mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
 			}()
 		}
 	}
@@ -81,18 +98,30 @@ func createInternetGw(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	if err = logic.SetNodeEgressPolicy(node.ID.String(), netid, request.EgressPolicy); err != nil {
+		logger.Log(1, "failed to persist egress policy for node", nodeid, ":", err.Error())
+	}
 	apiNode := node.ConvertToAPINode()
 	logger.Log(
 		1,
 		r.Header.Get("user"),
-		"created ingress gateway on node",
+		"created internet gateway on node",
 		nodeid,
 		"on network",
 		netid,
 	)
+	after, _ := json.Marshal(apiNode)
+	audit.Log(audit.Record{
+		Actor:    r.Header.Get("user"),
+		SourceIP: requestSourceIP(r),
+		Action:   audit.ActionCreateInternetGw,
+		Target:   nodeid,
+		After:    after,
+		Result:   audit.ResultSuccess,
+	})
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(apiNode)
-	go mq.PublishPeerUpdate(false)
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
 }
 
 // @Summary     Update an internet gateway
@@ -134,6 +163,11 @@ func updateInternetGw(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
 		return
 	}
+	if err = logic.ValidateEgressPolicy(netid, request.EgressPolicy); err != nil {
+		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "badrequest"))
+		return
+	}
+	before, _ := json.Marshal(node.ConvertToAPINode())
 	logic.UnsetInternetGw(&node)
 	logic.SetInternetGw(&node, request)
 	err = logic.UpsertNode(&node)
@@ -141,18 +175,31 @@ func updateInternetGw(w http.ResponseWriter, r *http.Request) {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	if err = logic.SetNodeEgressPolicy(node.ID.String(), netid, request.EgressPolicy); err != nil {
+		logger.Log(1, "failed to persist egress policy for node", nodeid, ":", err.Error())
+	}
 	apiNode := node.ConvertToAPINode()
 	logger.Log(
 		1,
 		r.Header.Get("user"),
-		"created ingress gateway on node",
+		"updated internet gateway on node",
 		nodeid,
 		"on network",
 		netid,
 	)
+	after, _ := json.Marshal(apiNode)
+	audit.Log(audit.Record{
+		Actor:    r.Header.Get("user"),
+		SourceIP: requestSourceIP(r),
+		Action:   audit.ActionUpdateInternetGw,
+		Target:   nodeid,
+		Before:   before,
+		After:    after,
+		Result:   audit.ResultSuccess,
+	})
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(apiNode)
-	go mq.PublishPeerUpdate(false)
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
 }
 
 // @Summary     Delete an internet gateway
@@ -174,22 +221,36 @@ func deleteInternetGw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before, _ := json.Marshal(node.ConvertToAPINode())
 	logic.UnsetInternetGw(&node)
 	err = logic.UpsertNode(&node)
 	if err != nil {
 		logic.ReturnErrorResponse(w, r, logic.FormatError(err, "internal"))
 		return
 	}
+	if err = logic.DeleteNodeEgressPolicy(node.ID.String()); err != nil {
+		logger.Log(1, "failed to delete egress policy for node", nodeid, ":", err.Error())
+	}
 	apiNode := node.ConvertToAPINode()
 	logger.Log(
 		1,
 		r.Header.Get("user"),
-		"created ingress gateway on node",
+		"deleted internet gateway on node",
 		nodeid,
 		"on network",
 		netid,
 	)
+	after, _ := json.Marshal(apiNode)
+	audit.Log(audit.Record{
+		Actor:    r.Header.Get("user"),
+		SourceIP: requestSourceIP(r),
+		Action:   audit.ActionDeleteInternetGw,
+		Target:   nodeid,
+		Before:   before,
+		After:    after,
+		Result:   audit.ResultSuccess,
+	})
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(apiNode)
-	go mq.PublishPeerUpdate(false)
+	mq.Coalescer.Enqueue(mq.PeerUpdateIntent{})
 }
@@ -0,0 +1,27 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/mq"
+)
+
+func init() {
+	HttpHandlers = append(HttpHandlers, mqMetricsHandlers)
+}
+
+func mqMetricsHandlers(r *mux.Router) {
+	r.HandleFunc("/api/server/mq_metrics", logic.SecurityCheck(true, http.HandlerFunc(getMqMetrics))).Methods(http.MethodGet)
+}
+
+// @Summary     Get peer update coalescer metrics
+// @Router      /api/server/mq_metrics [get]
+// @Tags        PRO
+// @Success     200 {object} mq.CoalescerMetrics
+func getMqMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mq.Coalescer.Metrics())
+}
@@ -0,0 +1,146 @@
+// Package audit provides a structured audit trail for security-sensitive actions
+// (gateway mutations, OAuth denials) that previously only surfaced as free-form
+// logger.Log strings.
+package audit
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/logger"
+)
+
+const auditTableName = "auditlogs"
+
+// Record is a single structured audit entry.
+type Record struct {
+	ID        string          `json:"id"`
+	Actor     string          `json:"actor"`
+	SourceIP  string          `json:"source_ip"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	Result    string          `json:"result"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Action identifiers for the call sites wired up so far.
+const (
+	ActionCreateInternetGw   = "create_internet_gw"
+	ActionUpdateInternetGw   = "update_internet_gw"
+	ActionDeleteInternetGw   = "delete_internet_gw"
+	ActionOauthUserNotFound  = "oauth_user_not_found"
+	ActionOauthUserNotAllow  = "oauth_user_not_allowed"
+	ActionOauthNotConfigured = "oauth_not_configured"
+)
+
+// Result values for Record.Result.
+const (
+	ResultSuccess = "success"
+	ResultDenied  = "denied"
+	ResultError   = "error"
+)
+
+// Sink receives a copy of every audit record as it's logged, for consumers that
+// want real-time delivery (stdout, a log file, an MQTT topic) in addition to the
+// database copy that backs the /api/audit query endpoint.
+type Sink interface {
+	Write(record Record) error
+}
+
+var (
+	sinksMu sync.Mutex
+	sinks   []Sink
+)
+
+// RegisterSink adds a sink that receives every future audit record. Packages that
+// own a delivery mechanism (e.g. mq's MQTT client) call this from an init().
+func RegisterSink(s Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, s)
+}
+
+func init() {
+	RegisterSink(StdoutSink{})
+}
+
+// Log persists record to the database, so it can be queried via Query, and fans it
+// out to every registered sink. ID and Timestamp are always set by Log.
+func Log(record Record) {
+	record.ID = uuid.NewString()
+	record.Timestamp = time.Now().UTC()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		logger.Log(1, "audit: failed to marshal record:", err.Error())
+		return
+	}
+	if err := database.Insert(record.ID, string(data), auditTableName); err != nil {
+		logger.Log(1, "audit: failed to persist record:", err.Error())
+	}
+
+	sinksMu.Lock()
+	active := append([]Sink{}, sinks...)
+	sinksMu.Unlock()
+	for _, sink := range active {
+		if err := sink.Write(record); err != nil {
+			logger.Log(1, "audit: sink write failed:", err.Error())
+		}
+	}
+}
+
+// Filter narrows a Query; zero-valued fields are ignored.
+type Filter struct {
+	Actor  string
+	Action string
+	From   time.Time
+	To     time.Time
+}
+
+// Query returns every persisted audit record matching filter, newest first.
+func Query(filter Filter) ([]Record, error) {
+	rows, err := database.FetchRecords(auditTableName)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return []Record{}, nil
+		}
+		return nil, err
+	}
+	records := make([]Record, 0, len(rows))
+	for _, row := range rows {
+		var record Record
+		if err := json.Unmarshal([]byte(row), &record); err != nil {
+			continue
+		}
+		if matchesFilter(record, filter) {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+	return records, nil
+}
+
+// matchesFilter reports whether record satisfies every non-zero field of filter.
+func matchesFilter(record Record, filter Filter) bool {
+	if filter.Actor != "" && record.Actor != filter.Actor {
+		return false
+	}
+	if filter.Action != "" && record.Action != filter.Action {
+		return false
+	}
+	if !filter.From.IsZero() && record.Timestamp.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && record.Timestamp.After(filter.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchesFilter(t *testing.T) {
+	base := Record{
+		Actor:     "alice",
+		Action:    ActionCreateInternetGw,
+		Timestamp: time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter matches everything", Filter{}, true},
+		{"actor match", Filter{Actor: "alice"}, true},
+		{"actor mismatch", Filter{Actor: "bob"}, false},
+		{"action match", Filter{Action: ActionCreateInternetGw}, true},
+		{"action mismatch", Filter{Action: ActionDeleteInternetGw}, false},
+		{"before range", Filter{From: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{"after range excludes", Filter{From: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}, false},
+		{"to range excludes", Filter{To: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesFilter(base, tt.filter); got != tt.want {
+				t.Errorf("matchesFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// StdoutSink writes each record as a single JSON line to stdout. It is registered
+// by default so audit records are never silently dropped when no other sink has
+// been configured.
+type StdoutSink struct{}
+
+// Write implements Sink.
+func (StdoutSink) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// FileSink appends each record as a JSON line to Path.
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileSink constructs a FileSink writing to path.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{Path: path}
+}
+
+// Write implements Sink.
+func (f *FileSink) Write(record Record) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = file.Write(data)
+	return err
+}
@@ -0,0 +1,127 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+)
+
+const nodeEgressPolicyTableName = "nodeegresspolicies"
+
+// ValidateEgressPolicy checks that an egress policy is internally consistent
+// (CIDRs and DNS upstream parse, ports are in range, a CIDR isn't both allowed
+// and denied) and that it doesn't conflict with network's own address range: a
+// denied CIDR that covers the network's address range would cut internal VPN
+// traffic off from the gateway node, which is never the intent of an egress
+// (internet-bound) policy.
+func ValidateEgressPolicy(network string, policy models.EgressPolicy) error {
+	denied := make(map[string]struct{}, len(policy.DeniedCIDRs))
+	for _, cidr := range policy.DeniedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.New("invalid denied CIDR: " + cidr)
+		}
+		denied[cidr] = struct{}{}
+	}
+	for _, cidr := range policy.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return errors.New("invalid allowed CIDR: " + cidr)
+		}
+		if _, ok := denied[cidr]; ok {
+			return errors.New("CIDR cannot be both allowed and denied: " + cidr)
+		}
+	}
+	for _, port := range policy.AllowedPorts {
+		if port < 1 || port > 65535 {
+			return errors.New("allowed port out of range: must be 1-65535")
+		}
+	}
+	if policy.BandwidthCeilingKbps < 0 {
+		return errors.New("bandwidth ceiling cannot be negative")
+	}
+	if policy.DNSFilterUpstream != "" {
+		if net.ParseIP(policy.DNSFilterUpstream) == nil {
+			if _, _, err := net.SplitHostPort(policy.DNSFilterUpstream); err != nil {
+				return errors.New("invalid DNS filter upstream: " + policy.DNSFilterUpstream)
+			}
+		}
+	}
+	if err := validateEgressPolicyAgainstNetworkRange(network, policy); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateEgressPolicyAgainstNetworkRange rejects a denied CIDR that overlaps the
+// network's own address range(s), since denying it would block intra-network VPN
+// traffic through the gateway rather than just internet-bound egress.
+func validateEgressPolicyAgainstNetworkRange(network string, policy models.EgressPolicy) error {
+	if len(policy.DeniedCIDRs) == 0 {
+		return nil
+	}
+	netw, err := GetNetwork(network)
+	if err != nil {
+		return err
+	}
+	for _, ranged := range []string{netw.AddressRange, netw.AddressRange6} {
+		if ranged == "" {
+			continue
+		}
+		_, networkNet, err := net.ParseCIDR(ranged)
+		if err != nil {
+			continue
+		}
+		for _, cidr := range policy.DeniedCIDRs {
+			_, deniedNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			if networkNet.Contains(deniedNet.IP) || deniedNet.Contains(networkNet.IP) {
+				return errors.New("denied CIDR " + cidr + " conflicts with the network's own address range " + ranged)
+			}
+		}
+	}
+	return nil
+}
+
+// SetNodeEgressPolicy persists the egress policy attached to an internet gateway
+// node, keyed by node ID, so mq can look it up when publishing host/policy updates.
+func SetNodeEgressPolicy(nodeID, network string, policy models.EgressPolicy) error {
+	record := models.NodeEgressPolicy{
+		NodeID:    nodeID,
+		Network:   network,
+		Policy:    policy,
+		UpdatedAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return database.Insert(nodeID, string(data), nodeEgressPolicyTableName)
+}
+
+// GetNodeEgressPolicy retrieves the egress policy for a gateway node. The second
+// return value is false if the node has no policy configured.
+func GetNodeEgressPolicy(nodeID string) (models.NodeEgressPolicy, bool, error) {
+	var record models.NodeEgressPolicy
+	data, err := database.FetchRecord(nodeEgressPolicyTableName, nodeID)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return record, false, nil
+		}
+		return record, false, err
+	}
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return record, false, err
+	}
+	return record, true, nil
+}
+
+// DeleteNodeEgressPolicy removes a gateway node's egress policy, e.g. when the
+// node stops being an internet gateway.
+func DeleteNodeEgressPolicy(nodeID string) error {
+	return database.DeleteRecord(nodeEgressPolicyTableName, nodeID)
+}
@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+func TestValidateEgressPolicyInternalConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  models.EgressPolicy
+		wantErr bool
+	}{
+		{
+			name:   "empty policy is valid",
+			policy: models.EgressPolicy{},
+		},
+		{
+			// DeniedCIDRs is intentionally empty: a non-empty one falls through to
+			// validateEgressPolicyAgainstNetworkRange, which needs a real network
+			// lookup and isn't exercised by this table.
+			name:   "valid CIDRs and port",
+			policy: models.EgressPolicy{AllowedCIDRs: []string{"10.0.0.0/24"}, AllowedPorts: []int{443}},
+		},
+		{
+			name:    "invalid denied CIDR",
+			policy:  models.EgressPolicy{DeniedCIDRs: []string{"not-a-cidr"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid allowed CIDR",
+			policy:  models.EgressPolicy{AllowedCIDRs: []string{"not-a-cidr"}},
+			wantErr: true,
+		},
+		{
+			name:    "CIDR both allowed and denied",
+			policy:  models.EgressPolicy{AllowedCIDRs: []string{"10.0.0.0/24"}, DeniedCIDRs: []string{"10.0.0.0/24"}},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			policy:  models.EgressPolicy{AllowedPorts: []int{70000}},
+			wantErr: true,
+		},
+		{
+			name:    "negative bandwidth ceiling",
+			policy:  models.EgressPolicy{BandwidthCeilingKbps: -1},
+			wantErr: true,
+		},
+		{
+			name:    "invalid DNS filter upstream",
+			policy:  models.EgressPolicy{DNSFilterUpstream: "not-an-ip-or-hostport"},
+			wantErr: true,
+		},
+		{
+			name:   "valid DNS filter upstream",
+			policy: models.EgressPolicy{DNSFilterUpstream: "1.1.1.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// No DeniedCIDRs overlap check is exercised here since that branch
+			// requires a network lookup; these cases all stop before it.
+			err := ValidateEgressPolicy("network1", tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEgressPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/database"
+	"github.com/gravitl/netmaker/models"
+)
+
+const internetGwGroupTableName = "internetgwgroups"
+
+var defaultInetGwHealthCheck = models.InternetGwHealthCheck{
+	Target:           "1.1.1.1:53",
+	IntervalSeconds:  10,
+	FailureThreshold: 3,
+}
+
+// ListInternetGwGroups - returns all the internet gateway failover groups configured for a network
+func ListInternetGwGroups(network string) ([]models.InternetGwGroup, error) {
+	records, err := database.FetchRecords(internetGwGroupTableName)
+	if err != nil {
+		if database.IsEmptyRecord(err) {
+			return []models.InternetGwGroup{}, nil
+		}
+		return nil, err
+	}
+	groups := []models.InternetGwGroup{}
+	for _, record := range records {
+		var group models.InternetGwGroup
+		if err := json.Unmarshal([]byte(record), &group); err != nil {
+			continue
+		}
+		if group.Network == network {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// GetInternetGwGroup - fetches a single internet gateway failover group by ID
+func GetInternetGwGroup(groupID string) (models.InternetGwGroup, error) {
+	var group models.InternetGwGroup
+	data, err := database.FetchRecord(internetGwGroupTableName, groupID)
+	if err != nil {
+		return group, err
+	}
+	if err := json.Unmarshal([]byte(data), &group); err != nil {
+		return group, err
+	}
+	return group, nil
+}
+
+// CreateInternetGwGroup - validates and persists a new internet gateway failover group
+func CreateInternetGwGroup(network string, req models.InternetGwGroupReq) (models.InternetGwGroup, error) {
+	var group models.InternetGwGroup
+	if err := ValidateInternetGwGroupReq(network, req); err != nil {
+		return group, err
+	}
+	now := time.Now().UTC()
+	group = models.InternetGwGroup{
+		ID:             uuid.NewString(),
+		Network:        network,
+		Name:           req.Name,
+		GwNodeIDs:      req.GwNodeIDs,
+		ActiveGwNodeID: req.GwNodeIDs[0],
+		Clients:        req.Clients,
+		HealthCheck:    req.HealthCheck,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if group.HealthCheck.Target == "" {
+		group.HealthCheck = defaultInetGwHealthCheck
+	}
+	if err := upsertInternetGwGroup(group); err != nil {
+		return group, err
+	}
+	return group, nil
+}
+
+// UpdateInternetGwGroup - validates and overwrites an existing internet gateway failover group
+func UpdateInternetGwGroup(groupID string, req models.InternetGwGroupReq) (models.InternetGwGroup, error) {
+	existing, err := GetInternetGwGroup(groupID)
+	if err != nil {
+		return existing, err
+	}
+	if err := ValidateInternetGwGroupReq(existing.Network, req); err != nil {
+		return existing, err
+	}
+	existing.Name = req.Name
+	existing.GwNodeIDs = req.GwNodeIDs
+	existing.Clients = req.Clients
+	if req.HealthCheck.Target != "" {
+		existing.HealthCheck = req.HealthCheck
+	}
+	if !containsString(existing.GwNodeIDs, existing.ActiveGwNodeID) {
+		existing.ActiveGwNodeID = existing.GwNodeIDs[0]
+	}
+	existing.UpdatedAt = time.Now().UTC()
+	if err := upsertInternetGwGroup(existing); err != nil {
+		return existing, err
+	}
+	return existing, nil
+}
+
+// DeleteInternetGwGroup - removes an internet gateway failover group
+func DeleteInternetGwGroup(groupID string) error {
+	return database.DeleteRecord(internetGwGroupTableName, groupID)
+}
+
+// ValidateInternetGwGroupReq - ensures a failover group request references a non-empty,
+// duplicate-free priority list of gateway nodes
+func ValidateInternetGwGroupReq(network string, req models.InternetGwGroupReq) error {
+	if req.Name == "" {
+		return errors.New("gateway group name is required")
+	}
+	if len(req.GwNodeIDs) == 0 {
+		return errors.New("at least one gateway node is required")
+	}
+	seen := make(map[string]struct{}, len(req.GwNodeIDs))
+	for _, nodeID := range req.GwNodeIDs {
+		if _, ok := seen[nodeID]; ok {
+			return errors.New("duplicate gateway node in priority list: " + nodeID)
+		}
+		seen[nodeID] = struct{}{}
+	}
+	for _, nodeID := range req.GwNodeIDs {
+		node, err := GetNodeByID(nodeID)
+		if err != nil {
+			return errors.New("gateway node not found: " + nodeID)
+		}
+		if node.Network != network {
+			return errors.New("gateway node does not belong to network: " + nodeID)
+		}
+	}
+	return nil
+}
+
+// PromoteInternetGwGroupMember - marks the next-priority gateway as active for a group,
+// rewriting the affected nodes' InetNodeReq so peer updates route clients through it
+func PromoteInternetGwGroupMember(group *models.InternetGwGroup, newActiveGwNodeID string) error {
+	if !containsString(group.GwNodeIDs, newActiveGwNodeID) {
+		return errors.New("node is not a member of the gateway group: " + newActiveGwNodeID)
+	}
+	oldActive := group.ActiveGwNodeID
+	if oldActive == newActiveGwNodeID {
+		return nil
+	}
+
+	oldGwNode, err := GetNodeByID(oldActive)
+	if err == nil {
+		UnsetInternetGw(&oldGwNode)
+		if err := UpsertNode(&oldGwNode); err != nil {
+			return err
+		}
+	}
+
+	newGwNode, err := GetNodeByID(newActiveGwNodeID)
+	if err != nil {
+		return err
+	}
+	SetInternetGw(&newGwNode, models.InetNodeReq{InetNodeClientIDs: group.Clients})
+	if err := UpsertNode(&newGwNode); err != nil {
+		return err
+	}
+
+	group.ActiveGwNodeID = newActiveGwNodeID
+	group.UpdatedAt = time.Now().UTC()
+	return upsertInternetGwGroup(*group)
+}
+
+func upsertInternetGwGroup(group models.InternetGwGroup) error {
+	data, err := json.Marshal(group)
+	if err != nil {
+		return err
+	}
+	return database.Insert(group.ID, string(data), internetGwGroupTableName)
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+func TestValidateInternetGwGroupReq(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.InternetGwGroupReq
+		wantErr bool
+	}{
+		{
+			name:    "missing name",
+			req:     models.InternetGwGroupReq{GwNodeIDs: []string{"node1"}},
+			wantErr: true,
+		},
+		{
+			name:    "no gateway nodes",
+			req:     models.InternetGwGroupReq{Name: "group1"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate gateway node",
+			req:     models.InternetGwGroupReq{Name: "group1", GwNodeIDs: []string{"node1", "node1"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateInternetGwGroupReq("network1", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInternetGwGroupReq() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// EgressProbeRequest is published to a gateway host asking it to test its own
+// internet egress against Target, so gateway health is evaluated from the
+// host's actual vantage point instead of the netmaker server's.
+type EgressProbeRequest struct {
+	RequestID string `json:"request_id"`
+	Target    string `json:"target"`
+}
+
+// EgressProbeResponse is the gateway host's reply to an EgressProbeRequest,
+// published back to the server on the host's egress probe response topic.
+type EgressProbeResponse struct {
+	RequestID string `json:"request_id"`
+	Healthy   bool   `json:"healthy"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// NodeEgressPolicy - the egress policy currently attached to an internet gateway
+// node, persisted independently of the node record so mq can look it up when
+// publishing host/policy updates.
+type NodeEgressPolicy struct {
+	NodeID    string       `json:"node_id"`
+	Network   string       `json:"network"`
+	Policy    EgressPolicy `json:"policy"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// InetNodeReq - request body for marking a node as an internet gateway and
+// configuring which clients route their internet traffic through it. EgressPolicy
+// is attached here (rather than as a separate request type) so a single
+// create/update call can set both in one round trip.
+type InetNodeReq struct {
+	InetNodeClientIDs []string     `json:"inet_node_client_ids"`
+	EgressPolicy      EgressPolicy `json:"egress_policy,omitempty"`
+}
+
+// EgressPolicy - per-node egress controls applied to traffic leaving an internet
+// gateway, so operators get real multi-tenant control instead of "route
+// everything out this node".
+type EgressPolicy struct {
+	// AllowedCIDRs, when non-empty, restricts egress to only these destination
+	// ranges; everything else is denied.
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
+	// DeniedCIDRs is always enforced, even when AllowedCIDRs is empty.
+	DeniedCIDRs []string `json:"denied_cidrs,omitempty"`
+	// AllowedPorts restricts egress to these L4 ports; empty means all ports.
+	AllowedPorts []int `json:"allowed_ports,omitempty"`
+	// BandwidthCeilingKbps caps the gateway's total egress throughput with a
+	// single tc class on its egress interface, shared by all clients; it is not
+	// applied per client. 0 means unlimited.
+	BandwidthCeilingKbps int `json:"bandwidth_ceiling_kbps,omitempty"`
+	// DNSFilterUpstream, when set, is the upstream resolver the gateway should
+	// forward client DNS queries to instead of the network's default.
+	DNSFilterUpstream string `json:"dns_filter_upstream,omitempty"`
+}
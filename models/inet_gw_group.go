@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// InternetGwGroup - a prioritized list of internet gateway nodes for a network that
+// the server fails over between automatically when the active gateway stops
+// passing health checks.
+type InternetGwGroup struct {
+	ID      string `json:"id" validate:"required"`
+	Network string `json:"network" validate:"required"`
+	Name    string `json:"name" validate:"required"`
+	// GwNodeIDs is ordered by priority, highest first. The node at index 0
+	// becomes the active gateway when the group is created; on failover the
+	// highest-priority healthy member is promoted. A recovered higher-priority
+	// node does not take over again until the active gateway fails.
+	GwNodeIDs []string `json:"gw_node_ids" validate:"required,min=1"`
+	// ActiveGwNodeID is the node ID currently serving as the gateway for
+	// the group's clients. It is maintained by the failover health-checker.
+	ActiveGwNodeID string `json:"active_gw_node_id"`
+	// Clients is the set of node IDs that should route their internet
+	// traffic through the group's active gateway.
+	Clients     []string              `json:"clients"`
+	HealthCheck InternetGwHealthCheck `json:"health_check"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// InternetGwHealthCheck - configuration for how a gateway group's active
+// member is probed and when a failover should be triggered.
+type InternetGwHealthCheck struct {
+	// Target is the egress endpoint probed from the gateway node, e.g. "1.1.1.1:53".
+	Target string `json:"target"`
+	// IntervalSeconds is how often the probe runs.
+	IntervalSeconds int `json:"interval_seconds"`
+	// FailureThreshold is the number of consecutive failed probes required
+	// before the group fails over to the next-priority gateway.
+	FailureThreshold int `json:"failure_threshold"`
+}
+
+// InternetGwGroupReq - request body for creating/updating an InternetGwGroup.
+type InternetGwGroupReq struct {
+	Name        string                `json:"name" validate:"required"`
+	GwNodeIDs   []string              `json:"gw_node_ids" validate:"required,min=1"`
+	Clients     []string              `json:"clients"`
+	HealthCheck InternetGwHealthCheck `json:"health_check"`
+}
+
+// InternetGwFailoverEvent - emitted over MQ whenever a group's active
+// gateway changes due to a failed health check.
+type InternetGwFailoverEvent struct {
+	Network      string    `json:"network"`
+	GroupID      string    `json:"group_id"`
+	FromGwNodeID string    `json:"from_gw_node_id"`
+	ToGwNodeID   string    `json:"to_gw_node_id"`
+	Reason       string    `json:"reason"`
+	Timestamp    time.Time `json:"timestamp"`
+}
@@ -0,0 +1,35 @@
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gravitl/netmaker/logic/audit"
+	"github.com/gravitl/netmaker/servercfg"
+)
+
+// MQTTAuditSink publishes every audit record to audit/{server} so external log
+// aggregators can subscribe without hitting the /api/audit query endpoint.
+type MQTTAuditSink struct{}
+
+// Write implements audit.Sink.
+func (MQTTAuditSink) Write(record audit.Record) error {
+	if !servercfg.IsMessageQueueBackend() {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	topic := fmt.Sprintf("audit/%s", servercfg.GetServer())
+	token := mqclient.Publish(topic, 0, false, data)
+	if !token.WaitTimeout(MQ_TIMEOUT * time.Second) {
+		return fmt.Errorf("connection timeout publishing audit event")
+	}
+	return token.Error()
+}
+
+func init() {
+	audit.RegisterSink(MQTTAuditSink{})
+}
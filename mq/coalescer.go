@@ -0,0 +1,217 @@
+package mq
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+)
+
+// defaultCoalesceWindow is how long the coalescer waits after the first intent in a
+// batch before computing and publishing peer diffs, so bursts of mutations collapse
+// into a single MQTT fan-out instead of one per mutation.
+const defaultCoalesceWindow = 500 * time.Millisecond
+
+// maxCoalescerQueue bounds how many update intents are buffered between flushes;
+// once exceeded, the oldest intent is dropped and the batch is widened to a full
+// peer recompute of every host, which covers the dropped intent's hosts. The
+// dropped intent's deleted node and clients are not carried into the flush.
+const maxCoalescerQueue = 256
+
+// PeerUpdateIntent describes a single change that may affect peer connectivity.
+// Coalescer merges these together before recomputing and diffing peer payloads.
+type PeerUpdateIntent struct {
+	DeletedNode    *models.Node
+	DeletedClients []models.ExtClient
+	// ChangedHostIDs, when non-empty, restricts the recompute to the given hosts
+	// instead of every host on the server; an empty slice means "recompute all".
+	ChangedHostIDs []string
+}
+
+// CoalescerMetrics is a point-in-time snapshot of PeerUpdateCoalescer activity,
+// suitable for exposing on a metrics/debug endpoint.
+type CoalescerMetrics struct {
+	QueueDepth        int
+	PublishedUpdates  uint64
+	SuppressedUpdates uint64
+	PerHostPublishes  map[string]uint64
+}
+
+// PeerUpdateCoalescer batches PublishPeerUpdate intents behind a debounce window and
+// only publishes to hosts whose peer payload actually changed since the last publish.
+type PeerUpdateCoalescer struct {
+	mu       sync.Mutex
+	window   time.Duration
+	queue    []PeerUpdateIntent
+	flushing bool
+
+	lastHostHash    map[string]string
+	publishedCount  uint64
+	suppressedCount uint64
+	perHostCount    map[string]uint64
+}
+
+// Coalescer is the process-wide peer update coalescer. Call Coalescer.Enqueue instead
+// of mq.PublishPeerUpdate directly so bursts of mutations share one MQTT fan-out.
+var Coalescer = NewPeerUpdateCoalescer(defaultCoalesceWindow)
+
+// NewPeerUpdateCoalescer constructs a coalescer with the given debounce window.
+func NewPeerUpdateCoalescer(window time.Duration) *PeerUpdateCoalescer {
+	return &PeerUpdateCoalescer{
+		window:       window,
+		lastHostHash: make(map[string]string),
+		perHostCount: make(map[string]uint64),
+	}
+}
+
+// Enqueue queues an update intent and schedules a flush after the debounce window if
+// one isn't already pending.
+func (c *PeerUpdateCoalescer) Enqueue(intent PeerUpdateIntent) {
+	c.mu.Lock()
+	if len(c.queue) >= maxCoalescerQueue {
+		c.queue = c.queue[1:]
+		intent.ChangedHostIDs = nil
+	}
+	c.queue = append(c.queue, intent)
+	shouldSchedule := !c.flushing
+	c.flushing = true
+	c.mu.Unlock()
+
+	if shouldSchedule {
+		time.AfterFunc(c.window, c.flush)
+	}
+}
+
+// Metrics returns a snapshot of the coalescer's counters for observability.
+func (c *PeerUpdateCoalescer) Metrics() CoalescerMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	perHost := make(map[string]uint64, len(c.perHostCount))
+	for k, v := range c.perHostCount {
+		perHost[k] = v
+	}
+	return CoalescerMetrics{
+		QueueDepth:        len(c.queue),
+		PublishedUpdates:  atomic.LoadUint64(&c.publishedCount),
+		SuppressedUpdates: atomic.LoadUint64(&c.suppressedCount),
+		PerHostPublishes:  perHost,
+	}
+}
+
+func (c *PeerUpdateCoalescer) flush() {
+	c.mu.Lock()
+	intents := c.queue
+	c.queue = nil
+	c.flushing = false
+	c.mu.Unlock()
+
+	if len(intents) == 0 || !servercfg.IsMessageQueueBackend() {
+		return
+	}
+
+	deletedNodes, deletedClients, hostScope := mergeIntents(intents)
+
+	hosts, err := logic.GetAllHosts()
+	if err != nil {
+		logger.Log(1, "coalescer: failed to retrieve hosts:", err.Error())
+		return
+	}
+	allNodes, err := logic.GetAllNodes()
+	if err != nil {
+		logger.Log(1, "coalescer: failed to retrieve nodes:", err.Error())
+		return
+	}
+
+	for _, host := range hosts {
+		host := host
+		if hostScope != nil {
+			if _, ok := hostScope[host.ID.String()]; !ok {
+				continue
+			}
+		}
+		if len(deletedNodes) == 0 {
+			c.publishIfChanged(&host, allNodes, nil, deletedClients)
+			continue
+		}
+		// Fan out one publish per deleted node so a batch of several deletions
+		// that coalesce into one flush each get signalled to GetPeerUpdateForHost,
+		// instead of silently dropping all but the first.
+		for _, delNode := range deletedNodes {
+			c.publishIfChanged(&host, allNodes, delNode, deletedClients)
+		}
+	}
+}
+
+// publishIfChanged recomputes the peer payload for host, and only publishes it if its
+// hash differs from the last payload sent to that host.
+func (c *PeerUpdateCoalescer) publishIfChanged(host *models.Host, allNodes []models.Node, deletedNode *models.Node, deletedClients []models.ExtClient) {
+	peerUpdate, err := logic.GetPeerUpdateForHost("", host, allNodes, deletedNode, deletedClients)
+	if err != nil {
+		logger.Log(1, "coalescer: failed to compute peer update for host", host.ID.String(), ":", err.Error())
+		return
+	}
+	data, err := json.Marshal(&peerUpdate)
+	if err != nil {
+		logger.Log(1, "coalescer: failed to marshal peer update for host", host.ID.String(), ":", err.Error())
+		return
+	}
+	hash := hashPayload(data)
+
+	// Egress policy delivery has its own change-detection (see publishHostEgressPolicies)
+	// and must run even when the peer payload itself is unchanged, so a policy-only
+	// update isn't held hostage to the peer diff below.
+	publishHostEgressPolicies(host, allNodes)
+
+	hostID := host.ID.String()
+	c.mu.Lock()
+	unchanged := c.lastHostHash[hostID] == hash
+	c.mu.Unlock()
+	if unchanged {
+		atomic.AddUint64(&c.suppressedCount, 1)
+		return
+	}
+
+	if err := publish(host, "peers/host/"+hostID+"/"+servercfg.GetServer(), data); err != nil {
+		logger.Log(1, "failed to publish peer update to host", hostID, ": ", err.Error())
+		return
+	}
+	c.mu.Lock()
+	c.lastHostHash[hostID] = hash
+	c.perHostCount[hostID]++
+	c.mu.Unlock()
+	atomic.AddUint64(&c.publishedCount, 1)
+}
+
+// mergeIntents flattens a batch of intents into the inputs flush needs: every
+// deleted node, every deleted client, and the union of explicitly changed hosts
+// (nil if any intent requested a full recompute).
+func mergeIntents(intents []PeerUpdateIntent) (deletedNodes []*models.Node, deletedClients []models.ExtClient, hostScope map[string]struct{}) {
+	hostScope = map[string]struct{}{}
+	for _, intent := range intents {
+		if intent.DeletedNode != nil {
+			deletedNodes = append(deletedNodes, intent.DeletedNode)
+		}
+		deletedClients = append(deletedClients, intent.DeletedClients...)
+		if len(intent.ChangedHostIDs) == 0 {
+			hostScope = nil
+			continue
+		}
+		if hostScope != nil {
+			for _, id := range intent.ChangedHostIDs {
+				hostScope[id] = struct{}{}
+			}
+		}
+	}
+	return
+}
+
+func hashPayload(data []byte) string {
+	sum := sha256.Sum256(data)
+	return string(sum[:])
+}
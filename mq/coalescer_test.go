@@ -0,0 +1,61 @@
+package mq
+
+import (
+	"testing"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+func TestMergeIntents(t *testing.T) {
+	node1 := &models.Node{}
+	node2 := &models.Node{}
+	client := models.ExtClient{ClientID: "client1"}
+
+	intents := []PeerUpdateIntent{
+		{DeletedNode: node1, ChangedHostIDs: []string{"host1"}},
+		{DeletedNode: node2, DeletedClients: []models.ExtClient{client}, ChangedHostIDs: []string{"host2"}},
+	}
+
+	deletedNodes, deletedClients, hostScope := mergeIntents(intents)
+
+	if len(deletedNodes) != 2 {
+		t.Fatalf("expected 2 deleted nodes, got %d", len(deletedNodes))
+	}
+	if len(deletedClients) != 1 || deletedClients[0].ClientID != "client1" {
+		t.Fatalf("expected deleted client to carry through, got %+v", deletedClients)
+	}
+	if hostScope == nil {
+		t.Fatal("expected a non-nil host scope when every intent scopes its hosts")
+	}
+	if _, ok := hostScope["host1"]; !ok {
+		t.Error("expected host1 in host scope")
+	}
+	if _, ok := hostScope["host2"]; !ok {
+		t.Error("expected host2 in host scope")
+	}
+}
+
+func TestMergeIntentsFullRecomputeWins(t *testing.T) {
+	intents := []PeerUpdateIntent{
+		{ChangedHostIDs: []string{"host1"}},
+		{}, // no ChangedHostIDs means a full recompute is requested
+	}
+
+	_, _, hostScope := mergeIntents(intents)
+	if hostScope != nil {
+		t.Fatalf("expected nil host scope once any intent requests a full recompute, got %v", hostScope)
+	}
+}
+
+func TestHashPayloadStableAndDistinct(t *testing.T) {
+	a := hashPayload([]byte(`{"a":1}`))
+	b := hashPayload([]byte(`{"a":1}`))
+	c := hashPayload([]byte(`{"a":2}`))
+
+	if a != b {
+		t.Error("expected identical payloads to hash identically")
+	}
+	if a == c {
+		t.Error("expected different payloads to hash differently")
+	}
+}
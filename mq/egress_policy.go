@@ -0,0 +1,116 @@
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+)
+
+// lastHostPolicyHash caches the hash of the last egress policy payload published
+// to each node, keyed by node ID, so publishHostEgressPolicies can be called on
+// every coalescer flush (even ones where the peer payload is unchanged) without
+// re-publishing a policy that hasn't changed.
+var (
+	lastHostPolicyHashMu sync.Mutex
+	lastHostPolicyHash   = make(map[string]string)
+)
+
+// HostEgressPolicyUpdate is the payload delivered on host/policy/{hostid}; the
+// client applies NftablesRules and TcRules to its local gateway interface.
+type HostEgressPolicyUpdate struct {
+	NodeID        string   `json:"node_id"`
+	NftablesRules []string `json:"nftables_rules"`
+	TcRules       []string `json:"tc_rules"`
+}
+
+// publishHostEgressPolicies pushes an egress policy update for every internet
+// gateway node on host that has one configured, skipping any node whose policy
+// is unchanged since the last publish. This is called on every coalescer flush
+// regardless of whether the peer payload itself changed, so this diff is what
+// keeps policy delivery from depending on the peer-payload diff.
+func publishHostEgressPolicies(host *models.Host, allNodes []models.Node) {
+	for _, node := range allNodes {
+		if node.HostID.String() != host.ID.String() || !node.IsInternetGateway {
+			continue
+		}
+		policyRecord, ok, err := logic.GetNodeEgressPolicy(node.ID.String())
+		if err != nil {
+			logger.Log(1, "failed to retrieve egress policy for node", node.ID.String(), ":", err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		update := HostEgressPolicyUpdate{
+			NodeID:        node.ID.String(),
+			NftablesRules: egressPolicyToNftablesRules(policyRecord.Policy),
+			TcRules:       egressPolicyToTcRules(policyRecord.Policy),
+		}
+		data, err := json.Marshal(update)
+		if err != nil {
+			logger.Log(1, "failed to marshal egress policy for node", node.ID.String(), ":", err.Error())
+			continue
+		}
+
+		nodeID := node.ID.String()
+		hash := hashPayload(data)
+		lastHostPolicyHashMu.Lock()
+		unchanged := lastHostPolicyHash[nodeID] == hash
+		lastHostPolicyHashMu.Unlock()
+		if unchanged {
+			continue
+		}
+
+		if err := publish(host, fmt.Sprintf("host/policy/%s", host.ID.String()), data); err != nil {
+			logger.Log(1, "failed to publish egress policy to host", host.ID.String(), ": ", err.Error())
+			continue
+		}
+		lastHostPolicyHashMu.Lock()
+		lastHostPolicyHash[nodeID] = hash
+		lastHostPolicyHashMu.Unlock()
+	}
+}
+
+// egressPolicyToNftablesRules translates an egress policy into nftables rules for
+// the gateway's egress chain, applied in order: explicit denies, then (if set) an
+// allow-list, then per-port restrictions.
+func egressPolicyToNftablesRules(policy models.EgressPolicy) []string {
+	var rules []string
+	for _, cidr := range policy.DeniedCIDRs {
+		rules = append(rules, fmt.Sprintf("add rule inet netmaker egress ip daddr %s drop", cidr))
+	}
+	if len(policy.AllowedCIDRs) > 0 {
+		for _, cidr := range policy.AllowedCIDRs {
+			rules = append(rules, fmt.Sprintf("add rule inet netmaker egress ip daddr %s accept", cidr))
+		}
+		rules = append(rules, "add rule inet netmaker egress drop")
+	}
+	if len(policy.AllowedPorts) > 0 {
+		for _, port := range policy.AllowedPorts {
+			rules = append(rules, fmt.Sprintf("add rule inet netmaker egress tcp dport %d accept", port))
+			rules = append(rules, fmt.Sprintf("add rule inet netmaker egress udp dport %d accept", port))
+		}
+		rules = append(rules, "add rule inet netmaker egress tcp drop")
+		rules = append(rules, "add rule inet netmaker egress udp drop")
+	}
+	if policy.DNSFilterUpstream != "" {
+		rules = append(rules, fmt.Sprintf("add rule inet netmaker egress udp dport 53 dnat to %s", policy.DNSFilterUpstream))
+	}
+	return rules
+}
+
+// egressPolicyToTcRules translates a bandwidth ceiling into a tc HTB rate limit
+// on the gateway's egress interface; empty when no ceiling is configured.
+func egressPolicyToTcRules(policy models.EgressPolicy) []string {
+	if policy.BandwidthCeilingKbps <= 0 {
+		return nil
+	}
+	return []string{
+		"tc qdisc add dev $EGRESS_IFACE root handle 1: htb default 10",
+		fmt.Sprintf("tc class add dev $EGRESS_IFACE parent 1: classid 1:10 htb rate %dkbit", policy.BandwidthCeilingKbps),
+	}
+}
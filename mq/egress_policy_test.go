@@ -0,0 +1,65 @@
+package mq
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gravitl/netmaker/models"
+)
+
+func TestEgressPolicyToNftablesRulesAllowedPorts(t *testing.T) {
+	rules := egressPolicyToNftablesRules(models.EgressPolicy{AllowedPorts: []int{80, 443}})
+
+	wantAccepts := []string{
+		"add rule inet netmaker egress tcp dport 80 accept",
+		"add rule inet netmaker egress udp dport 80 accept",
+		"add rule inet netmaker egress tcp dport 443 accept",
+		"add rule inet netmaker egress udp dport 443 accept",
+	}
+	for _, want := range wantAccepts {
+		if !containsRule(rules, want) {
+			t.Errorf("expected rules to contain %q, got %v", want, rules)
+		}
+	}
+
+	if !containsRule(rules, "add rule inet netmaker egress tcp drop") {
+		t.Error("expected a trailing tcp drop rule when ports are allow-listed")
+	}
+	if !containsRule(rules, "add rule inet netmaker egress udp drop") {
+		t.Error("expected a trailing udp drop rule when ports are allow-listed")
+	}
+
+	// Neither allowed port's accept rule should be shadowed by the other's: with
+	// a single shared "!=" rule (the bug this replaced), allowing 80 would drop
+	// 443 before its own accept rule ever ran.
+	dropIdx := -1
+	for i, rule := range rules {
+		if rule == "add rule inet netmaker egress tcp drop" {
+			dropIdx = i
+			break
+		}
+	}
+	for i, rule := range rules {
+		if strings.Contains(rule, "tcp dport") && strings.Contains(rule, "accept") && dropIdx != -1 && i > dropIdx {
+			t.Errorf("accept rule %q appears after the drop rule", rule)
+		}
+	}
+}
+
+func TestEgressPolicyToNftablesRulesNoAllowedPorts(t *testing.T) {
+	rules := egressPolicyToNftablesRules(models.EgressPolicy{})
+	for _, rule := range rules {
+		if strings.Contains(rule, "dport") {
+			t.Errorf("expected no port rules when AllowedPorts is empty, got %v", rules)
+		}
+	}
+}
+
+func containsRule(rules []string, want string) bool {
+	for _, r := range rules {
+		if r == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/models"
+	"github.com/gravitl/netmaker/servercfg"
+)
+
+// probeTimeout bounds how long probeGatewayEgress waits for a gateway host to
+// answer an egress probe request before treating it as unhealthy.
+const probeTimeout = 3 * time.Second
+
+// pendingProbes maps an in-flight egress probe's request ID to the channel its
+// response should be delivered on.
+var (
+	pendingProbesMu sync.Mutex
+	pendingProbes   = make(map[string]chan bool)
+)
+
+// egressProbeResponseTopic is the wildcard subscription covering every gateway
+// host's egress probe response topic.
+const egressProbeResponseTopic = "host/egress_probe_response/#"
+
+var subscribeEgressProbeResponsesOnce sync.Once
+
+// subscribeEgressProbeResponses subscribes HandleEgressProbeResponse to every
+// gateway host's response topic. It's idempotent and lazily called from
+// probeGatewayEgress so a probe never blocks out its own answer by running
+// before the subscription exists.
+func subscribeEgressProbeResponses() {
+	subscribeEgressProbeResponsesOnce.Do(func() {
+		if token := mqclient.Subscribe(egressProbeResponseTopic, 0, HandleEgressProbeResponse); !token.WaitTimeout(MQ_TIMEOUT*time.Second) || token.Error() != nil {
+			if token.Error() != nil {
+				logger.Log(0, "failed to subscribe to egress probe responses:", token.Error().Error())
+			} else {
+				logger.Log(0, "failed to subscribe to egress probe responses: connection timeout")
+			}
+		}
+	})
+}
+
+// probeGatewayEgress asks host itself to test egress to target over MQ and waits
+// for its answer, so the result reflects the gateway host's own connectivity
+// rather than the netmaker server's.
+func probeGatewayEgress(host *models.Host, target string) bool {
+	subscribeEgressProbeResponses()
+	requestID := uuid.NewString()
+	respCh := make(chan bool, 1)
+
+	pendingProbesMu.Lock()
+	pendingProbes[requestID] = respCh
+	pendingProbesMu.Unlock()
+	defer func() {
+		pendingProbesMu.Lock()
+		delete(pendingProbes, requestID)
+		pendingProbesMu.Unlock()
+	}()
+
+	data, err := json.Marshal(models.EgressProbeRequest{RequestID: requestID, Target: target})
+	if err != nil {
+		logger.Log(1, "failed to marshal egress probe request:", err.Error())
+		return false
+	}
+	topic := fmt.Sprintf("host/egress_probe/%s/%s", host.ID.String(), servercfg.GetServer())
+	if err := publish(host, topic, data); err != nil {
+		logger.Log(1, "failed to publish egress probe request to host", host.ID.String(), ": ", err.Error())
+		return false
+	}
+
+	select {
+	case healthy := <-respCh:
+		return healthy
+	case <-time.After(probeTimeout):
+		return false
+	}
+}
+
+// HandleEgressProbeResponse handles a gateway host's reply to an egress probe
+// request, published on host/egress_probe_response/{hostID}/{server}. It is
+// subscribed by subscribeEgressProbeResponses on the first probe.
+func HandleEgressProbeResponse(client mqtt.Client, msg mqtt.Message) {
+	var resp models.EgressProbeResponse
+	if err := json.Unmarshal(msg.Payload(), &resp); err != nil {
+		logger.Log(1, "failed to unmarshal egress probe response:", err.Error())
+		return
+	}
+	pendingProbesMu.Lock()
+	respCh, ok := pendingProbes[resp.RequestID]
+	pendingProbesMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case respCh <- resp.Healthy:
+	default:
+	}
+}
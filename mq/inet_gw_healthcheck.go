@@ -0,0 +1,196 @@
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gravitl/netmaker/logger"
+	"github.com/gravitl/netmaker/logic"
+	"github.com/gravitl/netmaker/models"
+)
+
+// gwFailureCounts tracks consecutive failed health checks per gateway node, keyed by node ID.
+var gwFailureCounts = make(map[string]int)
+
+// defaultHealthCheckIntervalSeconds is used when a group doesn't configure
+// InternetGwHealthCheck.IntervalSeconds.
+const defaultHealthCheckIntervalSeconds = 30
+
+// lastGroupCheck tracks when each group's health was last evaluated, keyed by
+// group ID, so checkInternetGwGroup can honor a per-group check interval
+// instead of running on every tick regardless of configuration.
+var (
+	lastGroupCheckMu sync.Mutex
+	lastGroupCheck   = make(map[string]time.Time)
+)
+
+// healthCheckTickInterval is how often the background health-check loop wakes
+// up to look for groups due for a check; the actual per-group cadence is
+// governed by HealthCheck.IntervalSeconds via groupDueForCheck.
+const healthCheckTickInterval = 10 * time.Second
+
+func init() {
+	go runInternetGwHealthChecks()
+}
+
+// runInternetGwHealthChecks ticks checkInternetGwGroups on its own schedule, off
+// the sendPeers hot path: probing a gateway over MQ can block for seconds, and
+// sendPeers is also responsible for timely scheduled peer updates.
+func runInternetGwHealthChecks() {
+	ticker := time.NewTicker(healthCheckTickInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		checkInternetGwGroups()
+	}
+}
+
+// checkInternetGwGroups runs one round of health checks across every network's
+// internet gateway failover groups, promoting the next-priority member when the
+// active gateway has failed enough consecutive probes.
+func checkInternetGwGroups() {
+	networks, err := logic.GetNetworks()
+	if err != nil {
+		logger.Log(3, "failed to retrieve networks for gateway health check:", err.Error())
+		return
+	}
+	for _, network := range networks {
+		groups, err := logic.ListInternetGwGroups(network.NetID)
+		if err != nil {
+			continue
+		}
+		for i := range groups {
+			checkInternetGwGroup(&groups[i])
+		}
+	}
+}
+
+func checkInternetGwGroup(group *models.InternetGwGroup) {
+	if group.ActiveGwNodeID == "" {
+		return
+	}
+	if !groupDueForCheck(group) {
+		return
+	}
+	activeNode, err := logic.GetNodeByID(group.ActiveGwNodeID)
+	if err != nil {
+		logger.Log(1, "gateway health check: active gateway node not found:", group.ActiveGwNodeID)
+		return
+	}
+	host, err := logic.GetHost(activeNode.HostID.String())
+	if err != nil {
+		return
+	}
+
+	target := group.HealthCheck.Target
+	if target == "" {
+		target = "1.1.1.1:53"
+	}
+	threshold := group.HealthCheck.FailureThreshold
+	if threshold <= 0 {
+		threshold = 3
+	}
+
+	if probeGatewayEgress(host, target) {
+		delete(gwFailureCounts, group.ActiveGwNodeID)
+		return
+	}
+
+	gwFailureCounts[group.ActiveGwNodeID]++
+	logger.Log(2, fmt.Sprintf("gateway %s failed health check (%d/%d) for group %s",
+		group.ActiveGwNodeID, gwFailureCounts[group.ActiveGwNodeID], threshold, group.ID))
+	if gwFailureCounts[group.ActiveGwNodeID] < threshold {
+		return
+	}
+
+	nextGwNodeID := nextGatewayInPriority(group)
+	if nextGwNodeID == "" || nextGwNodeID == group.ActiveGwNodeID {
+		return
+	}
+
+	oldActive := group.ActiveGwNodeID
+	if err := logic.PromoteInternetGwGroupMember(group, nextGwNodeID); err != nil {
+		logger.Log(1, "failed to fail over internet gateway group", group.ID, ":", err.Error())
+		return
+	}
+	delete(gwFailureCounts, oldActive)
+
+	logger.Log(0, fmt.Sprintf("internet gateway group %s failed over from %s to %s", group.ID, oldActive, nextGwNodeID))
+	event := models.InternetGwFailoverEvent{
+		Network:      group.Network,
+		GroupID:      group.ID,
+		FromGwNodeID: oldActive,
+		ToGwNodeID:   nextGwNodeID,
+		Reason:       fmt.Sprintf("%d consecutive failed health checks", threshold),
+		Timestamp:    time.Now().UTC(),
+	}
+	publishInternetGwFailoverEvent(event)
+	Coalescer.Enqueue(PeerUpdateIntent{})
+}
+
+// groupDueForCheck reports whether at least IntervalSeconds has elapsed since
+// group's last health check, defaulting to defaultHealthCheckIntervalSeconds
+// when unset, and records this check's time if so.
+func groupDueForCheck(group *models.InternetGwGroup) bool {
+	interval := group.HealthCheck.IntervalSeconds
+	if interval <= 0 {
+		interval = defaultHealthCheckIntervalSeconds
+	}
+
+	lastGroupCheckMu.Lock()
+	defer lastGroupCheckMu.Unlock()
+	now := time.Now()
+	if last, ok := lastGroupCheck[group.ID]; ok && now.Sub(last) < time.Duration(interval)*time.Second {
+		return false
+	}
+	lastGroupCheck[group.ID] = now
+	return true
+}
+
+// nextGatewayInPriority returns the highest-priority member of the group, excluding
+// the currently active gateway, that is actually passing a live egress probe right
+// now, or "" if no other member is currently healthy. Probing candidates before
+// promoting (rather than just taking the next entry in priority order) is what
+// stops failover from ping-ponging between two gateways that are both down.
+func nextGatewayInPriority(group *models.InternetGwGroup) string {
+	target := group.HealthCheck.Target
+	if target == "" {
+		target = "1.1.1.1:53"
+	}
+	for _, nodeID := range group.GwNodeIDs {
+		if nodeID == group.ActiveGwNodeID {
+			continue
+		}
+		candidate, err := logic.GetNodeByID(nodeID)
+		if err != nil {
+			continue
+		}
+		host, err := logic.GetHost(candidate.HostID.String())
+		if err != nil {
+			continue
+		}
+		if probeGatewayEgress(host, target) {
+			return nodeID
+		}
+	}
+	return ""
+}
+
+// publishInternetGwFailoverEvent emits a server-scoped MQ event so clients can log the
+// failover transition; it is not addressed to any single host so it bypasses publish().
+func publishInternetGwFailoverEvent(event models.InternetGwFailoverEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		logger.Log(1, "failed to marshal gateway failover event:", err.Error())
+		return
+	}
+	topic := fmt.Sprintf("events/inet_gw/%s", event.Network)
+	if token := mqclient.Publish(topic, 0, false, data); !token.WaitTimeout(MQ_TIMEOUT*time.Second) || token.Error() != nil {
+		if token.Error() != nil {
+			logger.Log(1, "failed to publish gateway failover event:", token.Error().Error())
+		} else {
+			logger.Log(1, "failed to publish gateway failover event: connection timeout")
+		}
+	}
+}
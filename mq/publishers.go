@@ -62,31 +62,34 @@ func PublishDeletedNodePeerUpdate(delNode *models.Node) error {
 	return err
 }
 
-// PublishDeletedClientPeerUpdate --- determines and publishes a peer update
-// to all the hosts with a deleted ext client to account for
+// PublishDeletedClientPeerUpdate --- routes a peer update for a deleted ext client
+// through Coalescer so the resulting fan-out is debounced and diffed like every
+// other peer update intent. IoT hosts don't route ext client traffic and are
+// excluded, same as the direct-publish path this replaces.
 func PublishDeletedClientPeerUpdate(delClient *models.ExtClient) error {
 	if !servercfg.IsMessageQueueBackend() {
 		return nil
 	}
-
 	hosts, err := logic.GetAllHosts()
 	if err != nil {
 		logger.Log(1, "err getting all hosts", err.Error())
 		return err
 	}
-	nodes, err := logic.GetAllNodes()
-	if err != nil {
-		return err
-	}
+	var changedHostIDs []string
 	for _, host := range hosts {
-		host := host
 		if host.OS != models.OS_Types.IoT {
-			if err = PublishSingleHostPeerUpdate(&host, nodes, nil, []models.ExtClient{*delClient}); err != nil {
-				logger.Log(1, "failed to publish peer update to host", host.ID.String(), ": ", err.Error())
-			}
+			changedHostIDs = append(changedHostIDs, host.ID.String())
 		}
 	}
-	return err
+	if len(changedHostIDs) == 0 {
+		// an empty host list would be read as a full recompute, IoT hosts included
+		return nil
+	}
+	Coalescer.Enqueue(PeerUpdateIntent{
+		DeletedClients: []models.ExtClient{*delClient},
+		ChangedHostIDs: changedHostIDs,
+	})
+	return nil
 }
 
 // PublishSingleHostPeerUpdate --- determines and publishes a peer update to one host
@@ -100,7 +103,11 @@ func PublishSingleHostPeerUpdate(host *models.Host, allNodes []models.Node, dele
 	if err != nil {
 		return err
 	}
-	return publish(host, fmt.Sprintf("peers/host/%s/%s", host.ID.String(), servercfg.GetServer()), data)
+	if err := publish(host, fmt.Sprintf("peers/host/%s/%s", host.ID.String(), servercfg.GetServer()), data); err != nil {
+		return err
+	}
+	publishHostEgressPolicies(host, allNodes)
+	return nil
 }
 
 // NodeUpdate -- publishes a node update
@@ -176,9 +183,7 @@ func PublishMqUpdatesForDeletedNode(node models.Node, sendNodeUpdate bool, gwCli
 			slog.Error("error publishing node update to node", "node", node.ID, "error", err)
 		}
 	}
-	if err := PublishDeletedNodePeerUpdate(&node); err != nil {
-		logger.Log(1, "error publishing peer update ", err.Error())
-	}
+	Coalescer.Enqueue(PeerUpdateIntent{DeletedNode: &node})
 	if servercfg.IsDNSMode() {
 		logic.SetDNS()
 	}